@@ -36,8 +36,20 @@ type CasbinRule struct {
 	V3    string
 	V4    string
 	V5    string
+	// Domain holds the "dom" token for RBAC-with-domains models, set by
+	// DomainAdapter so that tenants can be queried and indexed independently
+	// of the generic v0..v5 columns. Empty for non-domain-scoped policies.
+	Domain string `bson:"domain,omitempty"`
 }
 
+// defaultConnectTimeout is used when AdapterOptions.ConnectTimeout is not set.
+const defaultConnectTimeout = 10 * time.Second
+
+// errCannotSaveFilteredPolicy is returned by SavePolicy when the adapter
+// currently holds a filtered view of the policy, since saving it back would
+// silently drop the rules that were filtered out.
+var errCannotSaveFilteredPolicy = fmt.Errorf("cannot save a filtered policy")
+
 // adapter represents the MongoDB adapter for policy storage.
 type Adapter struct {
 	client   *mongo.Client
@@ -46,6 +58,41 @@ type Adapter struct {
 	dbName   string
 	collName string
 	filtered bool
+	indexes  IndexOptions
+
+	// domainOf, when set, computes the domain token for a rule about to be
+	// inserted, so every mutation path (AddPolicy, SavePolicy, the batch and
+	// update methods in batch.go, ...) stamps CasbinRule.Domain the same
+	// way instead of each caller having to remember to. Set by
+	// NewDomainAdapter; nil on a plain Adapter.
+	domainOf func(rule []string) string
+}
+
+// AdapterOptions groups the parameters accepted by NewAdapterWithOptions. It
+// allows callers to either hand the adapter an already-configured
+// *mongo.Client (Client) or let the adapter dial one itself using the
+// X.509 certificate settings below.
+type AdapterOptions struct {
+	// Client, if set, is used as-is and none of the connection fields below
+	// are consulted. Use this when the application already manages its own
+	// *mongo.Client (custom auth mechanism, pool tuning, replica set config, ...).
+	Client *mongo.Client
+
+	CAFilePath             string
+	CertificateKeyFilePath string
+	ReplicaSet             string
+	Servers                []string
+
+	Database       string
+	CollectionName string
+
+	// ConnectTimeout bounds the initial connect and ping. Defaults to
+	// defaultConnectTimeout when zero.
+	ConnectTimeout time.Duration
+
+	// Indexes controls whether and how CreateDBIndex manages indexes on the
+	// policy collection. The zero value creates the standard indexes.
+	Indexes IndexOptions
 }
 
 // NewAdapter is the constructor for Adapter. If database name is not provided
@@ -55,21 +102,67 @@ func NewAdapter(caFilePath,
 	replicaSet,
 	database string,
 	servers []string) (*Adapter, error) {
+	return NewAdapterWithOptions(AdapterOptions{
+		CAFilePath:             caFilePath,
+		CertificateKeyFilePath: certificateKeyFilePath,
+		ReplicaSet:             replicaSet,
+		Servers:                servers,
+		Database:               database,
+	})
+}
+
+// NewAdapterWithClient is the constructor for Adapter when the caller already
+// holds a configured *mongo.Client, e.g. one authenticated with something
+// other than MONGODB-X509. The client's lifecycle remains owned by the
+// caller; the adapter never calls Disconnect on it.
+func NewAdapterWithClient(client *mongo.Client, db, coll string) (*Adapter, error) {
+	return NewAdapterWithOptions(AdapterOptions{
+		Client:         client,
+		Database:       db,
+		CollectionName: coll,
+	})
+}
+
+// NewAdapterWithOptions is the constructor for Adapter. It is the most
+// flexible entry point: pass an existing *mongo.Client via
+// AdapterOptions.Client, or let the adapter dial one itself using the
+// MONGODB-X509 settings.
+func NewAdapterWithOptions(opts AdapterOptions) (*Adapter, error) {
+	collName := opts.CollectionName
+	if collName == "" {
+		collName = "casbin_rules"
+	}
+
 	a := &Adapter{
-		servers:  servers,
-		dbName:   database,
-		collName: "casbin_rules",
+		servers:  opts.Servers,
+		dbName:   opts.Database,
+		collName: collName,
 		filtered: false,
+		indexes:  opts.Indexes,
 	}
 
-	if err := a.ConnectToDB(caFilePath, certificateKeyFilePath, replicaSet); err != nil {
+	if opts.Client != nil {
+		a.client = opts.Client
+		a.ctx = context.Background()
+		if err := a.CreateDBIndex(); err != nil {
+			return nil, err
+		}
+		return a, nil
+	}
+
+	connectTimeout := opts.ConnectTimeout
+	if connectTimeout == 0 {
+		connectTimeout = defaultConnectTimeout
+	}
+
+	if err := a.ConnectToDB(opts.CAFilePath, opts.CertificateKeyFilePath, opts.ReplicaSet, connectTimeout); err != nil {
 		return nil, err
 	}
 
 	return a, nil
 }
 
-func (a *Adapter) ConnectToDB(caFilePath, certificateKeyFilePath, replicaSet string) error {
+func (a *Adapter) ConnectToDB(caFilePath, certificateKeyFilePath, replicaSet string, connectTimeout time.Duration) error {
 	var err error
 
 	uri_servers := strings.Join(a.servers[:], ",")
@@ -79,16 +172,20 @@ func (a *Adapter) ConnectToDB(caFilePath, certificateKeyFilePath, replicaSet str
 		AuthMechanism: "MONGODB-X509",
 		AuthSource:    "$external",
 	}
-	a.ctx, _ = context.WithTimeout(context.Background(), 10*time.Second)
-	a.client, err = mongo.Connect(a.ctx, options.Client().ApplyURI(uri).SetAuth(credential).SetReplicaSet(replicaSet))
+
+	connectCtx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+
+	a.client, err = mongo.Connect(connectCtx, options.Client().ApplyURI(uri).SetAuth(credential).SetReplicaSet(replicaSet))
 	if err != nil {
 		return err
 	}
 
-	if err := a.client.Ping(a.ctx, readpref.Primary()); err != nil {
+	if err := a.client.Ping(connectCtx, readpref.Primary()); err != nil {
 		return err
 	}
 	fmt.Println("Successfully connected to MongoDB")
+	a.ctx = context.Background()
 
 	if err = a.CreateDBIndex(); err != nil {
 		return err
@@ -96,37 +193,6 @@ func (a *Adapter) ConnectToDB(caFilePath, certificateKeyFilePath, replicaSet str
 	return nil
 }
 
-func (a *Adapter) CreateDBIndex() error {
-
-	collection := a.client.Database(a.dbName).Collection(a.collName)
-	indexes := []string{"ptype", "v0", "v1", "v2", "v3", "v4", "v5"}
-	for _, k := range indexes {
-		modIndex := mongo.IndexModel{
-			Keys: bson.M{
-				k: 1, // index in ascending order
-			}, Options: nil,
-		}
-		name, err := collection.Indexes().CreateOne(context.Background(), modIndex)
-		if err != nil {
-			return err
-		}
-		fmt.Println("Successfully create index", name)
-	}
-
-	/* only for debug
-	cursor, err := collection.Indexes().List(context.Background(), nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	var results []bson.M
-	if err := cursor.All(context.Background(), &results); err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(results)*/
-
-	return nil
-}
-
 func (a *Adapter) close() {
 	_ = a.client.Disconnect(a.ctx)
 }
@@ -186,29 +252,46 @@ LineEnd:
 
 // LoadPolicy loads policy from database.
 func (a *Adapter) LoadPolicy(model model.Model) error {
-	return a.LoadFilteredPolicy(model, bson.D{})
+	return a.LoadPolicyCtx(context.Background(), model)
+}
+
+// LoadPolicyCtx loads policy from database. The passed ctx bounds the
+// underlying Find and cursor iteration, allowing callers to enforce a
+// per-request timeout or propagate cancellation.
+func (a *Adapter) LoadPolicyCtx(ctx context.Context, model model.Model) error {
+	return a.LoadFilteredPolicyCtx(ctx, model, bson.D{})
 }
 
-// LoadFilteredPolicy loads matching policy lines from database. If not nil,
-// the filter must be a valid MongoDB selector.
+// LoadFilteredPolicy loads matching policy lines from database. filter may
+// be a casbin fileadapter.Filter for a portable, schema-agnostic filter, or a
+// raw MongoDB selector (bson.D/bson.M) for direct control over the query.
 func (a *Adapter) LoadFilteredPolicy(model model.Model, filter interface{}) error {
+	return a.LoadFilteredPolicyCtx(context.Background(), model, filter)
+}
+
+// LoadFilteredPolicyCtx loads matching policy lines from database. filter
+// may be a casbin fileadapter.Filter for a portable, schema-agnostic filter, or
+// a raw MongoDB selector (bson.D/bson.M) for direct control over the
+// query. The passed ctx bounds the underlying Find and cursor iteration.
+func (a *Adapter) LoadFilteredPolicyCtx(ctx context.Context, model model.Model, filter interface{}) error {
 	var err error
 
-	if filter == nil {
-		a.filtered = false
-	} else {
-		a.filtered = true
+	selector, err := toMongoSelector(filter)
+	if err != nil {
+		return err
 	}
+	a.filtered = !isEmptySelector(filter)
+
 	line := CasbinRule{}
 
 	collection := a.client.Database(a.dbName).Collection(a.collName)
-	cursor, err := collection.Find(context.Background(), filter)
+	cursor, err := collection.Find(ctx, selector)
 	if err != nil {
 		return err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
-	for cursor.Next(a.ctx) {
+	for cursor.Next(ctx) {
 		err := cursor.Decode(&line)
 		if err != nil {
 			log.Fatal(err)
@@ -251,10 +334,30 @@ func savePolicyLine(ptype string, rule []string) CasbinRule {
 	return line
 }
 
+// buildRuleLine builds the CasbinRule to persist for rule, stamping its
+// Domain field via a.domainOf when set. Every mutation path that inserts a
+// rule (AddPolicy, SavePolicy, and the batch/update methods in batch.go)
+// goes through this instead of calling savePolicyLine directly, so domain
+// stamping can't be forgotten on any one of them.
+func (a *Adapter) buildRuleLine(ptype string, rule []string) CasbinRule {
+	line := savePolicyLine(ptype, rule)
+	if a.domainOf != nil {
+		line.Domain = a.domainOf(rule)
+	}
+	return line
+}
+
 // SavePolicy saves policy to database.
 func (a *Adapter) SavePolicy(model model.Model) error {
+	return a.SavePolicyCtx(context.Background(), model)
+}
+
+// SavePolicyCtx saves policy to database. The passed ctx bounds the insert
+// operation; the drop of the existing collection runs on the adapter's own
+// background context and is not bounded by ctx.
+func (a *Adapter) SavePolicyCtx(ctx context.Context, model model.Model) error {
 	if a.filtered {
-		return fmt.Errorf("cannot save a filtered policy")
+		return errCannotSaveFilteredPolicy
 	}
 	if err := a.dropTable(); err != nil {
 		return err
@@ -264,20 +367,20 @@ func (a *Adapter) SavePolicy(model model.Model) error {
 
 	for ptype, ast := range model["p"] {
 		for _, rule := range ast.Policy {
-			line := savePolicyLine(ptype, rule)
+			line := a.buildRuleLine(ptype, rule)
 			lines = append(lines, &line)
 		}
 	}
 
 	for ptype, ast := range model["g"] {
 		for _, rule := range ast.Policy {
-			line := savePolicyLine(ptype, rule)
+			line := a.buildRuleLine(ptype, rule)
 			lines = append(lines, &line)
 		}
 	}
 
 	collection := a.client.Database(a.dbName).Collection(a.collName)
-	res, err := collection.InsertMany(context.Background(), lines)
+	res, err := collection.InsertMany(ctx, lines)
 	if err != nil {
 		return err
 	}
@@ -287,10 +390,16 @@ func (a *Adapter) SavePolicy(model model.Model) error {
 
 // AddPolicy adds a policy rule to the storage.
 func (a *Adapter) AddPolicy(sec string, ptype string, rule []string) error {
-	line := savePolicyLine(ptype, rule)
+	return a.AddPolicyCtx(context.Background(), sec, ptype, rule)
+}
+
+// AddPolicyCtx adds a policy rule to the storage. The passed ctx bounds the
+// underlying InsertOne.
+func (a *Adapter) AddPolicyCtx(ctx context.Context, sec string, ptype string, rule []string) error {
+	line := a.buildRuleLine(ptype, rule)
 
 	collection := a.client.Database(a.dbName).Collection(a.collName)
-	res, err := collection.InsertOne(context.Background(), line)
+	res, err := collection.InsertOne(ctx, line)
 	if err != nil {
 		return err
 	}
@@ -300,10 +409,16 @@ func (a *Adapter) AddPolicy(sec string, ptype string, rule []string) error {
 
 // RemovePolicy removes a policy rule from the storage.
 func (a *Adapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	return a.RemovePolicyCtx(context.Background(), sec, ptype, rule)
+}
+
+// RemovePolicyCtx removes a policy rule from the storage. The passed ctx
+// bounds the underlying DeleteOne.
+func (a *Adapter) RemovePolicyCtx(ctx context.Context, sec string, ptype string, rule []string) error {
 	line := savePolicyLine(ptype, rule)
 
 	collection := a.client.Database(a.dbName).Collection(a.collName)
-	res, err := collection.DeleteOne(context.Background(), line, nil)
+	res, err := collection.DeleteOne(ctx, line, nil)
 	if err != nil {
 		return err
 	}
@@ -313,6 +428,27 @@ func (a *Adapter) RemovePolicy(sec string, ptype string, rule []string) error {
 
 // RemoveFilteredPolicy removes policy rules that match the filter from the storage.
 func (a *Adapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	return a.RemoveFilteredPolicyCtx(context.Background(), sec, ptype, fieldIndex, fieldValues...)
+}
+
+// RemoveFilteredPolicyCtx removes policy rules that match the filter from
+// the storage. The passed ctx bounds the underlying DeleteMany.
+func (a *Adapter) RemoveFilteredPolicyCtx(ctx context.Context, sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	selector := fieldFilterSelector(ptype, fieldIndex, fieldValues...)
+
+	collection := a.client.Database(a.dbName).Collection(a.collName)
+	res, err := collection.DeleteMany(ctx, selector, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("deleted %v documents\n", res.DeletedCount)
+	return err
+}
+
+// fieldFilterSelector builds the MongoDB selector used by
+// RemoveFilteredPolicy: ptype plus whichever of v0..v5 fall within
+// [fieldIndex, fieldIndex+len(fieldValues)).
+func fieldFilterSelector(ptype string, fieldIndex int, fieldValues ...string) map[string]interface{} {
 	selector := make(map[string]interface{})
 	selector["ptype"] = ptype
 
@@ -347,11 +483,5 @@ func (a *Adapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int,
 		}
 	}
 
-	collection := a.client.Database(a.dbName).Collection(a.collName)
-	res, err := collection.DeleteMany(context.Background(), selector, nil)
-	if err != nil {
-		return err
-	}
-	fmt.Printf("deleted %v documents\n", res.DeletedCount)
-	return err
+	return selector
 }
@@ -0,0 +1,68 @@
+// Copyright 2018 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodbadapter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFieldFilterSelector(t *testing.T) {
+	tests := []struct {
+		name        string
+		ptype       string
+		fieldIndex  int
+		fieldValues []string
+		want        map[string]interface{}
+	}{
+		{
+			name:        "filter from v0",
+			ptype:       "p",
+			fieldIndex:  0,
+			fieldValues: []string{"alice"},
+			want:        map[string]interface{}{"ptype": "p", "v0": "alice"},
+		},
+		{
+			name:        "filter starting at v1",
+			ptype:       "p",
+			fieldIndex:  1,
+			fieldValues: []string{"data1", "read"},
+			want:        map[string]interface{}{"ptype": "p", "v1": "data1", "v2": "read"},
+		},
+		{
+			name:        "empty values are skipped",
+			ptype:       "g",
+			fieldIndex:  0,
+			fieldValues: []string{"alice", ""},
+			want:        map[string]interface{}{"ptype": "g", "v0": "alice"},
+		},
+		{
+			name:        "no field values at all",
+			ptype:       "p",
+			fieldIndex:  0,
+			fieldValues: nil,
+			want:        map[string]interface{}{"ptype": "p"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fieldFilterSelector(tt.ptype, tt.fieldIndex, tt.fieldValues...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fieldFilterSelector(%q, %d, %#v) = %#v, want %#v", tt.ptype, tt.fieldIndex, tt.fieldValues, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2018 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodbadapter
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// errMismatchedRuleCounts is returned by UpdatePolicies when oldRules and
+// newRules have different lengths.
+var errMismatchedRuleCounts = errors.New("mongodb-adapter: oldRules and newRules must have the same length")
+
+// AddPolicies adds policy rules to the storage. This is part of the
+// casbin BatchAdapter interface.
+func (a *Adapter) AddPolicies(sec string, ptype string, rules [][]string) error {
+	return a.AddPoliciesCtx(context.Background(), sec, ptype, rules)
+}
+
+// AddPoliciesCtx adds policy rules to the storage in a single InsertMany
+// round trip instead of one AddPolicy call per rule. The passed ctx bounds
+// the underlying InsertMany.
+func (a *Adapter) AddPoliciesCtx(ctx context.Context, sec string, ptype string, rules [][]string) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	lines := make([]interface{}, 0, len(rules))
+	for _, rule := range rules {
+		line := a.buildRuleLine(ptype, rule)
+		lines = append(lines, &line)
+	}
+
+	collection := a.client.Database(a.dbName).Collection(a.collName)
+	_, err := collection.InsertMany(ctx, lines)
+	return err
+}
+
+// RemovePolicies removes policy rules from the storage. This is part of
+// the casbin BatchAdapter interface.
+func (a *Adapter) RemovePolicies(sec string, ptype string, rules [][]string) error {
+	return a.RemovePoliciesCtx(context.Background(), sec, ptype, rules)
+}
+
+// RemovePoliciesCtx removes policy rules from the storage using a single
+// BulkWrite round trip. The passed ctx bounds the underlying BulkWrite.
+func (a *Adapter) RemovePoliciesCtx(ctx context.Context, sec string, ptype string, rules [][]string) error {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(rules))
+	for _, rule := range rules {
+		line := savePolicyLine(ptype, rule)
+		models = append(models, mongo.NewDeleteOneModel().SetFilter(line))
+	}
+
+	collection := a.client.Database(a.dbName).Collection(a.collName)
+	_, err := collection.BulkWrite(ctx, models)
+	return err
+}
+
+// UpdatePolicy updates a policy rule from oldRule to newRule. This is part
+// of the casbin UpdatableAdapter interface.
+func (a *Adapter) UpdatePolicy(sec string, ptype string, oldRule, newRule []string) error {
+	return a.UpdatePolicyCtx(context.Background(), sec, ptype, oldRule, newRule)
+}
+
+// UpdatePolicyCtx updates a policy rule from oldRule to newRule inside a
+// session transaction, so the delete-then-insert pair either both apply or
+// neither does on a replica-set deployment. The passed ctx bounds the
+// transaction.
+func (a *Adapter) UpdatePolicyCtx(ctx context.Context, sec string, ptype string, oldRule, newRule []string) error {
+	return a.withTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		return a.replacePolicyLine(sessCtx, ptype, oldRule, newRule)
+	})
+}
+
+// UpdatePolicies updates a set of policy rules from oldRules to newRules.
+// This is part of the casbin UpdatableAdapter interface.
+func (a *Adapter) UpdatePolicies(sec string, ptype string, oldRules, newRules [][]string) error {
+	return a.UpdatePoliciesCtx(context.Background(), sec, ptype, oldRules, newRules)
+}
+
+// UpdatePoliciesCtx updates a set of policy rules from oldRules to newRules
+// inside a single session transaction, rolling back all of them if any
+// individual replacement fails. The passed ctx bounds the transaction.
+func (a *Adapter) UpdatePoliciesCtx(ctx context.Context, sec string, ptype string, oldRules, newRules [][]string) error {
+	if len(oldRules) != len(newRules) {
+		return errMismatchedRuleCounts
+	}
+
+	return a.withTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		for i := range oldRules {
+			if err := a.replacePolicyLine(sessCtx, ptype, oldRules[i], newRules[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UpdateFilteredPolicies replaces every rule matching ptype and the
+// fieldIndex/fieldValues filter with newRules, returning the rules that
+// were replaced. This is part of the casbin UpdatableAdapter interface.
+func (a *Adapter) UpdateFilteredPolicies(sec string, ptype string, newRules [][]string, fieldIndex int, fieldValues ...string) ([][]string, error) {
+	return a.UpdateFilteredPoliciesCtx(context.Background(), sec, ptype, newRules, fieldIndex, fieldValues...)
+}
+
+// UpdateFilteredPoliciesCtx replaces every rule matching ptype and the
+// fieldIndex/fieldValues filter with newRules, inside a single session
+// transaction, and returns the rules that were replaced. The passed ctx
+// bounds the transaction.
+func (a *Adapter) UpdateFilteredPoliciesCtx(ctx context.Context, sec string, ptype string, newRules [][]string, fieldIndex int, fieldValues ...string) ([][]string, error) {
+	selector := fieldFilterSelector(ptype, fieldIndex, fieldValues...)
+
+	var oldRules [][]string
+	err := a.withTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		collection := a.client.Database(a.dbName).Collection(a.collName)
+
+		cursor, err := collection.Find(sessCtx, selector)
+		if err != nil {
+			return err
+		}
+		var matched []CasbinRule
+		if err := cursor.All(sessCtx, &matched); err != nil {
+			return err
+		}
+
+		oldRules = make([][]string, 0, len(matched))
+		for _, line := range matched {
+			oldRules = append(oldRules, ruleFromLine(line))
+		}
+
+		if _, err := collection.DeleteMany(sessCtx, selector); err != nil {
+			return err
+		}
+
+		if len(newRules) == 0 {
+			return nil
+		}
+
+		lines := make([]interface{}, 0, len(newRules))
+		for _, rule := range newRules {
+			line := a.buildRuleLine(ptype, rule)
+			lines = append(lines, &line)
+		}
+		_, err = collection.InsertMany(sessCtx, lines)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return oldRules, nil
+}
+
+// ruleFromLine converts a stored CasbinRule back into the []string form
+// casbin rules are represented as, dropping the trailing unused v-columns.
+// It is the inverse of savePolicyLine.
+func ruleFromLine(line CasbinRule) []string {
+	rule := []string{line.V0, line.V1, line.V2, line.V3, line.V4, line.V5}
+	for len(rule) > 0 && rule[len(rule)-1] == "" {
+		rule = rule[:len(rule)-1]
+	}
+	return rule
+}
+
+// replacePolicyLine deletes oldRule and inserts newRule using the given
+// session-bound context, so callers can compose several replacements into
+// one transaction.
+func (a *Adapter) replacePolicyLine(ctx context.Context, ptype string, oldRule, newRule []string) error {
+	oldLine := savePolicyLine(ptype, oldRule)
+	newLine := a.buildRuleLine(ptype, newRule)
+
+	collection := a.client.Database(a.dbName).Collection(a.collName)
+	if _, err := collection.DeleteOne(ctx, oldLine); err != nil {
+		return err
+	}
+	if _, err := collection.InsertOne(ctx, newLine); err != nil {
+		return err
+	}
+	return nil
+}
+
+// withTransaction runs fn inside a MongoDB session transaction via
+// mongo.Session.WithTransaction, so that a partial failure midway through a
+// batch update rolls back cleanly on replica-set deployments. WithTransaction
+// (rather than driving StartTransaction/CommitTransaction by hand) also
+// retries the transaction for us on a TransientTransactionError or
+// UnknownTransactionCommitResult, which a replica set can return for a
+// perfectly valid operation under concurrent writers.
+func (a *Adapter) withTransaction(ctx context.Context, fn func(sessCtx mongo.SessionContext) error) error {
+	return a.client.UseSession(ctx, func(sessCtx mongo.SessionContext) error {
+		_, err := sessCtx.WithTransaction(sessCtx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+			return nil, fn(sessCtx)
+		})
+		return err
+	})
+}
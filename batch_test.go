@@ -0,0 +1,63 @@
+// Copyright 2018 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodbadapter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRuleFromLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line CasbinRule
+		want []string
+	}{
+		{
+			name: "full rule",
+			line: CasbinRule{PType: "p", V0: "alice", V1: "data1", V2: "read"},
+			want: []string{"alice", "data1", "read"},
+		},
+		{
+			name: "trailing columns unused",
+			line: CasbinRule{PType: "g", V0: "alice", V1: "admin"},
+			want: []string{"alice", "admin"},
+		},
+		{
+			name: "empty rule",
+			line: CasbinRule{PType: "p"},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ruleFromLine(tt.line)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ruleFromLine(%+v) = %#v, want %#v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleFromLineRoundTrip(t *testing.T) {
+	rule := []string{"alice", "data1", "read"}
+	line := savePolicyLine("p", rule)
+
+	got := ruleFromLine(line)
+	if !reflect.DeepEqual(got, rule) {
+		t.Errorf("ruleFromLine(savePolicyLine(%q, %#v)) = %#v, want %#v", "p", rule, got, rule)
+	}
+}
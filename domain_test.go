@@ -0,0 +1,65 @@
+// Copyright 2018 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodbadapter
+
+import "testing"
+
+func TestDomainValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		domainIndex int
+		rule        []string
+		want        string
+	}{
+		{
+			name:        "default domain position",
+			domainIndex: defaultDomainIndex,
+			rule:        []string{"alice", "domain1", "data1", "read"},
+			want:        "domain1",
+		},
+		{
+			name:        "custom domain position",
+			domainIndex: 2,
+			rule:        []string{"alice", "data1", "domain1", "read"},
+			want:        "domain1",
+		},
+		{
+			name:        "rule shorter than domainIndex",
+			domainIndex: 5,
+			rule:        []string{"alice", "data1"},
+			want:        "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			da := &DomainAdapter{domainIndex: tt.domainIndex}
+			if got := da.domainValue(tt.rule); got != tt.want {
+				t.Errorf("domainValue(%#v) with domainIndex %d = %q, want %q", tt.rule, tt.domainIndex, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRuleLineStampsDomainViaDomainValue(t *testing.T) {
+	a := &Adapter{}
+	da := &DomainAdapter{Adapter: a, domainIndex: 1}
+	a.domainOf = da.domainValue
+
+	line := a.buildRuleLine("p", []string{"alice", "domain1", "data1", "read"})
+	if line.Domain != "domain1" {
+		t.Errorf("buildRuleLine with domainOf wired = %q, want %q", line.Domain, "domain1")
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodbadapter
+
+import (
+	"fmt"
+
+	fileadapter "github.com/casbin/casbin/v2/persist/file-adapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// toMongoSelector converts the filter argument accepted by
+// LoadFilteredPolicy into a MongoDB selector. A fileadapter.Filter (or
+// *fileadapter.Filter) is translated via filterToSelector; anything else
+// (bson.D, bson.M, nil, ...) is passed through unchanged, since it is
+// already expected to be a valid MongoDB selector.
+func toMongoSelector(filter interface{}) (interface{}, error) {
+	switch f := filter.(type) {
+	case fileadapter.Filter:
+		return filterToSelector(f), nil
+	case *fileadapter.Filter:
+		if f == nil {
+			return bson.D{}, nil
+		}
+		return filterToSelector(*f), nil
+	default:
+		return filter, nil
+	}
+}
+
+// isEmptySelector reports whether filter represents "no filter at all": a
+// nil interface, a nil *fileadapter.Filter, an all-nil/all-empty
+// fileadapter.Filter (by value or by non-nil pointer), or an empty
+// bson.D/bson.M selector. LoadPolicyCtx passes bson.D{} for a plain,
+// unfiltered load, so without the bson cases here a.filtered would end up
+// true on every ordinary LoadPolicy call and a subsequent SavePolicy would
+// wrongly refuse to run; the same holds for a caller-built fileadapter.Filter
+// whose P/G/G1..G5 all happen to be empty.
+func isEmptySelector(filter interface{}) bool {
+	switch f := filter.(type) {
+	case nil:
+		return true
+	case fileadapter.Filter:
+		return isEmptyFilter(f)
+	case *fileadapter.Filter:
+		return f == nil || isEmptyFilter(*f)
+	case bson.D:
+		return len(f) == 0
+	case bson.M:
+		return len(f) == 0
+	default:
+		return false
+	}
+}
+
+// isEmptyFilter reports whether every field of f is empty, i.e. f selects
+// every rule rather than a subset.
+func isEmptyFilter(f fileadapter.Filter) bool {
+	return len(f.P) == 0 && len(f.G) == 0 && len(f.G1) == 0 && len(f.G2) == 0 &&
+		len(f.G3) == 0 && len(f.G4) == 0 && len(f.G5) == 0
+}
+
+// filterToSelector translates casbin's fileadapter.Filter into the $or of
+// BSON selectors matching ptype=="p" with f.P's values against v0..vN, and
+// ptype=="g"/"g1".."g5" with the corresponding f.G/f.G1..f.G5 values. A
+// single clause is returned on its own without the $or, and a filter with
+// no values yields an empty selector that matches every rule. This
+// lets callers write filtered-load code that is portable across casbin
+// adapters instead of hand-building a MongoDB selector against this
+// adapter's schema.
+func filterToSelector(f fileadapter.Filter) bson.M {
+	var clauses []bson.M
+	add := func(ptype string, values []string) {
+		if sel := ptypeValuesSelector(ptype, values); sel != nil {
+			clauses = append(clauses, sel)
+		}
+	}
+
+	add("p", f.P)
+	add("g", f.G)
+	add("g1", f.G1)
+	add("g2", f.G2)
+	add("g3", f.G3)
+	add("g4", f.G4)
+	add("g5", f.G5)
+
+	switch len(clauses) {
+	case 0:
+		return bson.M{}
+	case 1:
+		return clauses[0]
+	default:
+		return bson.M{"$or": clauses}
+	}
+}
+
+// ptypeValuesSelector builds a selector matching ptype plus whichever of
+// v0..v5 have a non-empty value in values, positionally. It returns nil
+// when values is empty so an unused filter section (e.g. f.G when only
+// "p" rules are being filtered) is omitted from the selector entirely.
+func ptypeValuesSelector(ptype string, values []string) bson.M {
+	if len(values) == 0 {
+		return nil
+	}
+
+	selector := bson.M{"ptype": ptype}
+	for i, v := range values {
+		if v == "" || i > 5 {
+			continue
+		}
+		selector[fmt.Sprintf("v%d", i)] = v
+	}
+	return selector
+}
@@ -0,0 +1,127 @@
+// Copyright 2018 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodbadapter
+
+import (
+	"reflect"
+	"testing"
+
+	fileadapter "github.com/casbin/casbin/v2/persist/file-adapter"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestIsEmptySelector(t *testing.T) {
+	var nilFilter *fileadapter.Filter
+
+	tests := []struct {
+		name   string
+		filter interface{}
+		want   bool
+	}{
+		{name: "nil interface", filter: nil, want: true},
+		{name: "nil *fileadapter.Filter", filter: nilFilter, want: true},
+		{name: "empty bson.D", filter: bson.D{}, want: true},
+		{name: "empty bson.M", filter: bson.M{}, want: true},
+		{name: "non-empty bson.D", filter: bson.D{{Key: "ptype", Value: "p"}}, want: false},
+		{name: "non-empty bson.M", filter: bson.M{"ptype": "p"}, want: false},
+		{name: "non-empty fileadapter.Filter", filter: fileadapter.Filter{P: []string{"alice"}}, want: false},
+		{name: "empty fileadapter.Filter by value", filter: fileadapter.Filter{}, want: true},
+		{name: "non-nil but empty *fileadapter.Filter", filter: &fileadapter.Filter{}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isEmptySelector(tt.filter); got != tt.want {
+				t.Errorf("isEmptySelector(%#v) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterToSelector(t *testing.T) {
+	tests := []struct {
+		name string
+		f    fileadapter.Filter
+		want bson.M
+	}{
+		{
+			name: "empty filter",
+			f:    fileadapter.Filter{},
+			want: bson.M{},
+		},
+		{
+			name: "p only",
+			f:    fileadapter.Filter{P: []string{"alice", "data1"}},
+			want: bson.M{"ptype": "p", "v0": "alice", "v1": "data1"},
+		},
+		{
+			name: "p and g",
+			f:    fileadapter.Filter{P: []string{"alice"}, G: []string{"", "admin"}},
+			want: bson.M{"$or": []bson.M{
+				{"ptype": "p", "v0": "alice"},
+				{"ptype": "g", "v1": "admin"},
+			}},
+		},
+		{
+			name: "g1 through g5",
+			f:    fileadapter.Filter{G1: []string{"a"}, G5: []string{"b"}},
+			want: bson.M{"$or": []bson.M{
+				{"ptype": "g1", "v0": "a"},
+				{"ptype": "g5", "v0": "b"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterToSelector(tt.f)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterToSelector(%#v) = %#v, want %#v", tt.f, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPtypeValuesSelector(t *testing.T) {
+	if got := ptypeValuesSelector("p", nil); got != nil {
+		t.Errorf("ptypeValuesSelector with no values = %#v, want nil", got)
+	}
+
+	want := bson.M{"ptype": "p", "v0": "alice", "v2": "read"}
+	got := ptypeValuesSelector("p", []string{"alice", "", "read"})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ptypeValuesSelector(\"p\", ...) = %#v, want %#v", got, want)
+	}
+}
+
+func TestToMongoSelector(t *testing.T) {
+	selector, err := toMongoSelector(fileadapter.Filter{P: []string{"alice"}})
+	if err != nil {
+		t.Fatalf("toMongoSelector returned unexpected error: %v", err)
+	}
+	want := bson.M{"ptype": "p", "v0": "alice"}
+	if !reflect.DeepEqual(selector, want) {
+		t.Errorf("toMongoSelector(fileadapter.Filter{...}) = %#v, want %#v", selector, want)
+	}
+
+	raw := bson.M{"domain": "domain1"}
+	selector, err = toMongoSelector(raw)
+	if err != nil {
+		t.Fatalf("toMongoSelector returned unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(selector, raw) {
+		t.Errorf("toMongoSelector(bson.M) = %#v, want passthrough %#v", selector, raw)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodbadapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IndexOptions controls whether and how CreateDBIndex manages indexes on
+// the policy collection.
+type IndexOptions struct {
+	// Disabled skips automatic index creation entirely. Useful when
+	// connecting to a read-only replica, or when an operator manages
+	// indexes out of band.
+	Disabled bool
+}
+
+// legacyIndexFields lists the single-field indexes an older version of this
+// adapter created on every one of ptype/v0..v5. They were mostly redundant
+// with the compound index below and wasted storage, so CreateDBIndex drops
+// them the first time it runs against an existing collection.
+var legacyIndexFields = []string{"ptype", "v0", "v1", "v2", "v3", "v4", "v5"}
+
+// CreateDBIndex creates the indexes used to query and dedupe the policy
+// collection. It is called automatically by ConnectToDB and
+// NewAdapterWithOptions, and is a no-op when a.indexes.Disabled is set.
+func (a *Adapter) CreateDBIndex() error {
+	if a.indexes.Disabled {
+		return nil
+	}
+
+	collection := a.client.Database(a.dbName).Collection(a.collName)
+
+	if err := dropLegacyIndexes(collection); err != nil {
+		return err
+	}
+
+	queryIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "ptype", Value: 1},
+			{Key: "v0", Value: 1},
+			{Key: "v1", Value: 1},
+			{Key: "v2", Value: 1},
+		},
+	}
+	name, err := collection.Indexes().CreateOne(context.Background(), queryIndex)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Successfully create index", name)
+
+	uniqueIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "ptype", Value: 1},
+			{Key: "v0", Value: 1},
+			{Key: "v1", Value: 1},
+			{Key: "v2", Value: 1},
+			{Key: "v3", Value: 1},
+			{Key: "v4", Value: 1},
+			{Key: "v5", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	}
+	name, err = collection.Indexes().CreateOne(context.Background(), uniqueIndex)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Successfully create index", name)
+
+	domainIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "domain", Value: 1},
+			{Key: "ptype", Value: 1},
+			{Key: "v0", Value: 1},
+		},
+	}
+	name, err = collection.Indexes().CreateOne(context.Background(), domainIndex)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Successfully create index", name)
+
+	return nil
+}
+
+// dropLegacyIndexes drops the single-field indexes created by older
+// versions of this adapter, so a collection created before this migration
+// doesn't carry both the old and new index schemes side by side. Missing
+// indexes are not an error since a fresh collection never had them.
+func dropLegacyIndexes(collection *mongo.Collection) error {
+	for _, field := range legacyIndexFields {
+		_, err := collection.Indexes().DropOne(context.Background(), field+"_1")
+		if err != nil && !isIndexNotFoundErr(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func isIndexNotFoundErr(err error) bool {
+	return strings.Contains(err.Error(), "index not found")
+}
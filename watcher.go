@@ -0,0 +1,183 @@
+// Copyright 2018 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodbadapter
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// watcherStateCollection stores the resume token of the last change stream
+// event a Watcher processed, so a restarted watcher can pick up where it
+// left off instead of replaying the full oplog history.
+const watcherStateCollection = "casbin_watcher_state"
+
+// watcherStateID is the base document id used to persist the resume token.
+// It is combined with the watched collection name (see Watcher.stateDocID)
+// so that two Watchers sharding policies across different collections in
+// the same database don't read or write each other's resume token.
+const watcherStateID = "watcher_resume_token"
+
+type watcherState struct {
+	ID          string   `bson:"_id"`
+	ResumeToken bson.Raw `bson:"resume_token"`
+}
+
+// Watcher implements casbin's persist.Watcher interface on top of a MongoDB
+// change stream over the adapter's collection, so that policy changes made
+// by one enforcer instance are pushed to every other instance watching the
+// same collection without polling.
+type Watcher struct {
+	adapter  *Adapter
+	ctx      context.Context
+	cancel   context.CancelFunc
+	callback func(string)
+}
+
+// NewWatcher creates a Watcher over the collection used by a. The returned
+// Watcher immediately starts watching in a background goroutine; call
+// SetUpdateCallback to receive notifications and Close to stop watching.
+func NewWatcher(a *Adapter) (*Watcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	w := &Watcher{
+		adapter: a,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	if err := w.start(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// SetUpdateCallback sets the callback that is invoked whenever a policy
+// change is observed on the watched collection. This is part of casbin's
+// persist.Watcher interface.
+func (w *Watcher) SetUpdateCallback(callback func(string)) error {
+	w.callback = callback
+	return nil
+}
+
+// Update notifies other enforcer instances that the policy has changed.
+// The mongodb-adapter watcher observes writes directly through the change
+// stream on the shared collection, so local writes are already visible to
+// every watcher and there is nothing extra to publish here.
+func (w *Watcher) Update() error {
+	return nil
+}
+
+// Close stops the watcher's background goroutine and releases the change
+// stream cursor.
+func (w *Watcher) Close() {
+	w.cancel()
+}
+
+func (w *Watcher) collection() *mongo.Collection {
+	return w.adapter.client.Database(w.adapter.dbName).Collection(w.adapter.collName)
+}
+
+func (w *Watcher) stateCollection() *mongo.Collection {
+	return w.adapter.client.Database(w.adapter.dbName).Collection(watcherStateCollection)
+}
+
+// stateDocID returns the resume-token document id for this Watcher's
+// collection, so watchers over different collections in the same database
+// never collide on the same state document.
+func (w *Watcher) stateDocID() string {
+	return watcherStateID + ":" + w.adapter.collName
+}
+
+func (w *Watcher) start() error {
+	pipeline := mongo.Pipeline{}
+
+	opts := options.ChangeStream()
+	if token, err := w.loadResumeToken(); err != nil {
+		return err
+	} else if token != nil {
+		opts.SetResumeAfter(token)
+	}
+
+	stream, err := w.collection().Watch(w.ctx, pipeline, opts)
+	if err != nil {
+		return err
+	}
+
+	go w.watch(stream)
+
+	return nil
+}
+
+func (w *Watcher) watch(stream *mongo.ChangeStream) {
+	defer stream.Close(context.Background())
+
+	for stream.Next(w.ctx) {
+		var event bson.M
+		if err := stream.Decode(&event); err != nil {
+			fmt.Println("mongodb-adapter watcher: failed to decode change event:", err)
+			continue
+		}
+
+		if w.callback != nil {
+			if opType, ok := event["operationType"].(string); ok {
+				switch opType {
+				case "insert", "update", "delete", "replace":
+					w.callback(opType)
+				}
+			}
+		}
+
+		if err := w.saveResumeToken(stream.ResumeToken()); err != nil {
+			fmt.Println("mongodb-adapter watcher: failed to persist resume token:", err)
+		}
+	}
+
+	if err := stream.Err(); err != nil && w.ctx.Err() == nil {
+		fmt.Println("mongodb-adapter watcher: change stream closed with error:", err)
+	}
+}
+
+func (w *Watcher) loadResumeToken() (bson.Raw, error) {
+	var state watcherState
+	err := w.stateCollection().FindOne(context.Background(), bson.M{"_id": w.stateDocID()}).Decode(&state)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return state.ResumeToken, nil
+}
+
+func (w *Watcher) saveResumeToken(token bson.Raw) error {
+	if token == nil {
+		return nil
+	}
+
+	_, err := w.stateCollection().UpdateOne(
+		context.Background(),
+		bson.M{"_id": w.stateDocID()},
+		bson.M{"$set": bson.M{"resume_token": token}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
@@ -0,0 +1,43 @@
+// Copyright 2018 The casbin Authors. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodbadapter
+
+import "testing"
+
+func TestWatcherStateDocID(t *testing.T) {
+	tests := []struct {
+		collName string
+		want     string
+	}{
+		{collName: "casbin_rules", want: "watcher_resume_token:casbin_rules"},
+		{collName: "tenant_a_rules", want: "watcher_resume_token:tenant_a_rules"},
+	}
+
+	for _, tt := range tests {
+		w := &Watcher{adapter: &Adapter{collName: tt.collName}}
+		if got := w.stateDocID(); got != tt.want {
+			t.Errorf("stateDocID() with collName %q = %q, want %q", tt.collName, got, tt.want)
+		}
+	}
+}
+
+func TestWatcherStateDocIDDistinctPerCollection(t *testing.T) {
+	a := &Watcher{adapter: &Adapter{collName: "policies_a"}}
+	b := &Watcher{adapter: &Adapter{collName: "policies_b"}}
+
+	if a.stateDocID() == b.stateDocID() {
+		t.Errorf("watchers over different collections must not share a resume-token document id, got %q for both", a.stateDocID())
+	}
+}